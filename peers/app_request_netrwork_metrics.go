@@ -12,6 +12,12 @@ var (
 type AppRequestNetworkMetrics struct {
 	infoAPICallLatencyMS   prometheus.Histogram
 	pChainAPICallLatencyMS prometheus.Histogram
+
+	// validatorAppRequestLatencyMS and validatorAppRequestSuccesses are
+	// labeled by node ID so that each validator's AppRequest latency and
+	// outcome counts are exported individually.
+	validatorAppRequestLatencyMS *prometheus.HistogramVec
+	validatorAppRequestSuccesses *prometheus.CounterVec
 }
 
 func newAppRequestNetworkMetrics(registerer prometheus.Registerer) (*AppRequestNetworkMetrics, error) {
@@ -39,8 +45,47 @@ func newAppRequestNetworkMetrics(registerer prometheus.Registerer) (*AppRequestN
 	}
 	registerer.MustRegister(pChainAPICallLatencyMS)
 
+	validatorAppRequestLatencyMS := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "validator_app_request_latency_ms",
+			Help:    "Latency of AppRequests to a given validator in milliseconds",
+			Buckets: prometheus.ExponentialBucketsRange(10, 10000, 10),
+		},
+		[]string{"node_id"},
+	)
+	if validatorAppRequestLatencyMS == nil {
+		return nil, ErrFailedToCreateAppRequestNetworkMetrics
+	}
+	registerer.MustRegister(validatorAppRequestLatencyMS)
+
+	validatorAppRequestSuccesses := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "validator_app_request_total",
+			Help: "Count of AppRequests sent to a given validator, labeled by outcome",
+		},
+		[]string{"node_id", "outcome"},
+	)
+	if validatorAppRequestSuccesses == nil {
+		return nil, ErrFailedToCreateAppRequestNetworkMetrics
+	}
+	registerer.MustRegister(validatorAppRequestSuccesses)
+
 	return &AppRequestNetworkMetrics{
-		infoAPICallLatencyMS:   infoAPICallLatencyMS,
-		pChainAPICallLatencyMS: pChainAPICallLatencyMS,
+		infoAPICallLatencyMS:         infoAPICallLatencyMS,
+		pChainAPICallLatencyMS:       pChainAPICallLatencyMS,
+		validatorAppRequestLatencyMS: validatorAppRequestLatencyMS,
+		validatorAppRequestSuccesses: validatorAppRequestSuccesses,
 	}, nil
 }
+
+// ObserveValidatorAppRequest records the latency and outcome of an
+// AppRequest sent to nodeID as Prometheus metrics. Adaptive validator
+// selection does not read these metrics.
+func (m *AppRequestNetworkMetrics) ObserveValidatorAppRequest(nodeID string, latencyMS float64, success bool) {
+	m.validatorAppRequestLatencyMS.WithLabelValues(nodeID).Observe(latencyMS)
+	outcome := "success"
+	if !success {
+		outcome = "failure"
+	}
+	m.validatorAppRequestSuccesses.WithLabelValues(nodeID, outcome).Inc()
+}
@@ -0,0 +1,468 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/proto/pb/sdk"
+	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/icm-services/peers"
+	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// signingRequestTimeout bounds how long CreateSignedMessage waits for
+// validator responses to a single round of AppRequests before giving up on
+// outstanding validators and aggregating whatever it has collected so far.
+const signingRequestTimeout = 2 * time.Second
+
+// warpQuorumDenominatorAgg is the denominator quorumPercentage is expressed
+// against (e.g. 67 means 67/100 of total validator weight).
+const warpQuorumDenominatorAgg = 100
+
+var errNotEnoughSignatures = errors.New("failed to collect a threshold of signatures")
+
+// SignatureAggregator collects BLS signature shares from the validators of
+// a subnet and aggregates them into a warp.Message once a quorum of stake
+// has signed.
+type SignatureAggregator struct {
+	network          peers.AppRequestNetwork
+	logger           logging.Logger
+	messageCreator   message.Creator
+	currentRequestID atomic.Uint32
+	signatureCache   SignatureCache
+	metrics          *metrics.SignatureAggregatorMetrics
+
+	// queue, when set via SetMessageQueue, routes CreateSignedMessage and
+	// CreateSignedMessageForCaller through the per-subnet fairness scheduler
+	// instead of running inline; CreateSignedMessageWithProgress and
+	// CreateSignedMessages always run inline. It is nil by default so
+	// existing direct callers (and tests) are unaffected.
+	queue *MessageQueue
+
+	// validatorScores drives the adaptive validator selection in
+	// CreateSignedMessage; it always starts empty, which is equivalent to
+	// today's broadcast-to-all behavior until history accumulates.
+	validatorScores *ValidatorScoreTracker
+
+	// networkMetrics is optionally set via SetNetworkMetrics to mirror every
+	// validator AppRequest outcome recorded into validatorScores as
+	// Prometheus histograms/counters. It is nil by default so existing
+	// callers (and tests) are unaffected.
+	networkMetrics *peers.AppRequestNetworkMetrics
+
+	// batchMu and inflightBatches back CreateSignedMessages' coalescing of
+	// concurrent callers that request the same message bytes.
+	batchMu         sync.Mutex
+	inflightBatches map[string]*batchInflight
+}
+
+// NewSignatureAggregator creates a SignatureAggregator that aggregates
+// signatures over network, caching up to signatureCacheSize signature
+// shares in memory.
+func NewSignatureAggregator(
+	network peers.AppRequestNetwork,
+	logger logging.Logger,
+	messageCreator message.Creator,
+	signatureCacheSize uint64,
+	aggregatorMetrics *metrics.SignatureAggregatorMetrics,
+) (*SignatureAggregator, error) {
+	return &SignatureAggregator{
+		network:         network,
+		logger:          logger,
+		messageCreator:  messageCreator,
+		signatureCache:  NewInMemorySignatureCache(int(signatureCacheSize)),
+		metrics:         aggregatorMetrics,
+		validatorScores: NewValidatorScoreTracker(nil),
+		inflightBatches: make(map[string]*batchInflight),
+	}, nil
+}
+
+// SetSignatureCache overrides the default in-memory signature cache with
+// cache, e.g. a persistent BadgerDB or Redis backend built via
+// NewSignatureCache from the `signature-cache` config stanza.
+func (s *SignatureAggregator) SetSignatureCache(cache SignatureCache) {
+	s.signatureCache = cache
+	s.validatorScores = NewValidatorScoreTracker(cache)
+}
+
+// SetNetworkMetrics wires m into the aggregator so every AppRequest
+// round-trip recorded into validatorScores is also observed as Prometheus
+// histograms/counters.
+func (s *SignatureAggregator) SetNetworkMetrics(m *peers.AppRequestNetworkMetrics) {
+	s.networkMetrics = m
+}
+
+// SetMessageQueue routes subsequent CreateSignedMessage and
+// CreateSignedMessageForCaller calls through q, giving each subnet its fair,
+// stake-weighted share of concurrency instead of running them inline.
+// CreateSignedMessageWithProgress and CreateSignedMessages do not use q.
+func (s *SignatureAggregator) SetMessageQueue(q *MessageQueue) {
+	s.queue = q
+}
+
+// ValidatorScores returns the tracker backing this aggregator's adaptive
+// validator selection, for read-only inspection by e.g. the
+// /debug/validators endpoint.
+func (s *SignatureAggregator) ValidatorScores() *ValidatorScoreTracker {
+	return s.validatorScores
+}
+
+// CreateSignedMessage aggregates signatures for unsignedMessage from the
+// validators of its signing subnet until quorumPercentage of validator
+// weight has signed, or until every connected validator has been asked.
+// Each round of requests waits up to signingRequestTimeout, and a round that
+// falls short of quorum is followed by a wider one, so a call can take
+// several multiples of signingRequestTimeout.
+//
+// inputSigningSubnet is used only if the signing subnet cannot be derived
+// from unsignedMessage's source chain (e.g. the lookup call itself fails);
+// otherwise the subnet is always resolved from the message.
+func (s *SignatureAggregator) CreateSignedMessage(
+	unsignedMessage *warp.UnsignedMessage,
+	justification []byte,
+	inputSigningSubnet ids.ID,
+	quorumPercentage uint64,
+) (*warp.Message, error) {
+	return s.CreateSignedMessageForCaller(unsignedMessage, justification, inputSigningSubnet, quorumPercentage, "", time.Time{})
+}
+
+// CreateSignedMessageForCaller is CreateSignedMessage with caller
+// attribution and an optional deadline, for use by API handlers that know
+// the identity of the caller (remote IP, API key) and want it accounted for
+// by the per-subnet fairness scheduler (see MessageQueue).
+func (s *SignatureAggregator) CreateSignedMessageForCaller(
+	unsignedMessage *warp.UnsignedMessage,
+	justification []byte,
+	inputSigningSubnet ids.ID,
+	quorumPercentage uint64,
+	callerKey string,
+	deadline time.Time,
+) (*warp.Message, error) {
+	signingSubnetID, err := s.resolveSigningSubnet(unsignedMessage, inputSigningSubnet)
+	if err != nil {
+		return nil, err
+	}
+
+	run := func() (*warp.Message, error) {
+		return s.aggregateForSubnet(unsignedMessage, justification, signingSubnetID, quorumPercentage, nil)
+	}
+
+	if s.queue == nil {
+		return run()
+	}
+	return s.queue.Submit(signingSubnetID, callerKey, deadline, run)
+}
+
+// CreateSignedMessageWithProgress behaves like CreateSignedMessage, but
+// additionally invokes onProgress with the running weighted stake collected
+// so far every time an additional validator's share arrives, for callers
+// (e.g. the gRPC streaming API) that want to surface incremental progress
+// towards quorum rather than only the final result. onProgress is called
+// synchronously from the aggregation goroutine, so it must not block.
+func (s *SignatureAggregator) CreateSignedMessageWithProgress(
+	unsignedMessage *warp.UnsignedMessage,
+	justification []byte,
+	inputSigningSubnet ids.ID,
+	quorumPercentage uint64,
+	onProgress func(collectedWeight, totalWeight uint64),
+) (*warp.Message, error) {
+	signingSubnetID, err := s.resolveSigningSubnet(unsignedMessage, inputSigningSubnet)
+	if err != nil {
+		return nil, err
+	}
+	return s.aggregateForSubnet(unsignedMessage, justification, signingSubnetID, quorumPercentage, onProgress)
+}
+
+func (s *SignatureAggregator) resolveSigningSubnet(
+	unsignedMessage *warp.UnsignedMessage,
+	inputSigningSubnet ids.ID,
+) (ids.ID, error) {
+	signingSubnetID, err := s.network.GetSubnetID(unsignedMessage.SourceChainID)
+	if err != nil {
+		if inputSigningSubnet == ids.Empty {
+			return ids.Empty, fmt.Errorf("failed to get subnet ID for chain %s: %w", unsignedMessage.SourceChainID, err)
+		}
+		return inputSigningSubnet, nil
+	}
+	return signingSubnetID, nil
+}
+
+func (s *SignatureAggregator) aggregateForSubnet(
+	unsignedMessage *warp.UnsignedMessage,
+	justification []byte,
+	signingSubnetID ids.ID,
+	quorumPercentage uint64,
+	onProgress func(collectedWeight, totalWeight uint64),
+) (*warp.Message, error) {
+	s.network.TrackSubnet(signingSubnetID)
+
+	connectedValidators, err := s.network.GetConnectedCanonicalValidators(signingSubnetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connected validators for subnet %s: %w", signingSubnetID, err)
+	}
+
+	if connectedValidators.TotalValidatorWeight == 0 {
+		return nil, fmt.Errorf("no signatures possible: subnet %s has no validators", signingSubnetID)
+	}
+	if connectedValidators.ConnectedWeight*warpQuorumDenominatorAgg < connectedValidators.TotalValidatorWeight*quorumPercentage {
+		return nil, fmt.Errorf(
+			"failed to connect to a threshold of stake: connected %d of %d total",
+			connectedValidators.ConnectedWeight,
+			connectedValidators.TotalValidatorWeight,
+		)
+	}
+
+	shares := make(map[ids.NodeID][]byte)
+	s.loadCachedShares(unsignedMessage, connectedValidators, shares)
+
+	var onShare func()
+	if onProgress != nil {
+		onShare = func() {
+			onProgress(weightOfShares(connectedValidators, shares), connectedValidators.TotalValidatorWeight)
+		}
+		onShare()
+	}
+
+	selected := SelectValidatorsForRound(connectedValidators.ValidatorSet, s.validatorScores, 0)
+	for {
+		if err := s.requestShares(unsignedMessage, justification, signingSubnetID, selected, shares, onShare); err != nil {
+			s.logger.Warn("Failed to request signature shares", zap.Error(err))
+		}
+
+		if weightOfShares(connectedValidators, shares)*warpQuorumDenominatorAgg >= connectedValidators.TotalValidatorWeight*quorumPercentage {
+			break
+		}
+		if len(selected) >= len(connectedValidators.ValidatorSet) {
+			break
+		}
+		// Quorum wasn't reached with this round's fan-out; widen it, keeping
+		// whatever shares were already collected, and try again.
+		selected = SelectValidatorsForRound(connectedValidators.ValidatorSet, s.validatorScores, len(selected))
+	}
+
+	return s.aggregateWeightedSignature(unsignedMessage, connectedValidators, quorumPercentage, shares)
+}
+
+// weightOfShares sums the stake weight of every validator in
+// connectedValidators with at least one collected share in shares, for
+// reporting quorum progress as shares arrive.
+func weightOfShares(connectedValidators *peers.ConnectedCanonicalValidators, shares map[ids.NodeID][]byte) uint64 {
+	var collected uint64
+	for _, validator := range connectedValidators.ValidatorSet {
+		for _, nodeID := range validator.NodeIDs {
+			if _, ok := shares[nodeID]; ok {
+				collected += validator.Weight
+				break
+			}
+		}
+	}
+	return collected
+}
+
+// loadCachedShares fills shares with any signature already present in the
+// signature cache for unsignedMessage, so a restarted process doesn't have
+// to re-query validators it already collected a share from.
+func (s *SignatureAggregator) loadCachedShares(
+	unsignedMessage *warp.UnsignedMessage,
+	connectedValidators *peers.ConnectedCanonicalValidators,
+	shares map[ids.NodeID][]byte,
+) {
+	if s.signatureCache == nil {
+		return
+	}
+	msgID := unsignedMessage.ID()
+	for _, validator := range connectedValidators.ValidatorSet {
+		pubKey := hex.EncodeToString(validator.PublicKeyBytes)
+		sig, ok := s.signatureCache.Get(SignatureCacheKey{UnsignedMessageID: msgID, ValidatorPublicKey: pubKey})
+		if !ok {
+			continue
+		}
+		for _, nodeID := range validator.NodeIDs {
+			shares[nodeID] = sig
+		}
+	}
+}
+
+// requestShares sends AppRequests to validators (that don't already have a
+// cached share) and populates shares with whatever responses arrive before
+// signingRequestTimeout.
+func (s *SignatureAggregator) requestShares(
+	unsignedMessage *warp.UnsignedMessage,
+	justification []byte,
+	signingSubnetID ids.ID,
+	validators []*warp.Validator,
+	shares map[ids.NodeID][]byte,
+	onShare func(),
+) error {
+	chainID := unsignedMessage.SourceChainID
+	requestID := s.currentRequestID.Add(1)
+
+	var nodeIDs set.Set[ids.NodeID]
+	pubKeyByNodeID := make(map[ids.NodeID][]byte)
+	for _, validator := range validators {
+		for _, nodeID := range validator.NodeIDs {
+			if _, cached := shares[nodeID]; cached {
+				continue
+			}
+			nodeIDs.Add(nodeID)
+			pubKeyByNodeID[nodeID] = validator.PublicKeyBytes
+			s.network.RegisterAppRequest(ids.RequestID{
+				NodeID:    nodeID,
+				ChainID:   chainID,
+				RequestID: requestID,
+				Op:        byte(message.AppResponseOp),
+			})
+		}
+	}
+
+	if nodeIDs.Len() == 0 {
+		return nil
+	}
+
+	responseChan := s.network.RegisterRequestID(requestID, nodeIDs.Len())
+
+	requestBytes, err := proto.Marshal(&sdk.SignatureRequest{
+		Message:       unsignedMessage.Bytes(),
+		Justification: justification,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal signature request: %w", err)
+	}
+
+	outMsg, err := s.messageCreator.AppRequest(chainID, requestID, signingRequestTimeout, requestBytes)
+	if err != nil {
+		return fmt.Errorf("failed to build AppRequest: %w", err)
+	}
+
+	sentAt := time.Now()
+	pending := nodeIDs.Clone()
+	s.network.Send(outMsg, nodeIDs, signingSubnetID, subnets.NoOpAllower)
+
+	deadline := time.After(signingRequestTimeout)
+	remaining := nodeIDs.Len()
+	for remaining > 0 {
+		select {
+		case inboundMsg, ok := <-responseChan:
+			if !ok {
+				s.recordOutcomes(pending, time.Since(sentAt), false)
+				return nil
+			}
+			remaining--
+			nodeID := inboundMsg.NodeID()
+			pending.Remove(nodeID)
+			appResponse, ok := inboundMsg.Message().(*message.AppResponse)
+			if !ok {
+				s.recordOutcome(nodeID, time.Since(sentAt), false)
+				continue
+			}
+			var resp sdk.SignatureResponse
+			if err := proto.Unmarshal(appResponse.AppBytes, &resp); err != nil {
+				s.logger.Debug("Failed to unmarshal signature response", zap.Stringer("nodeID", nodeID), zap.Error(err))
+				s.recordOutcome(nodeID, time.Since(sentAt), false)
+				continue
+			}
+			shares[nodeID] = resp.Signature
+			s.recordOutcome(nodeID, time.Since(sentAt), true)
+			if pubKey, ok := pubKeyByNodeID[nodeID]; ok {
+				s.cacheShare(unsignedMessage, pubKey, resp.Signature)
+			}
+			if onShare != nil {
+				onShare()
+			}
+		case <-deadline:
+			s.recordOutcomes(pending, signingRequestTimeout, false)
+			return nil
+		}
+	}
+	return nil
+}
+
+// recordOutcome reports a single validator's AppRequest round-trip time and
+// outcome to validatorScores and, if set, networkMetrics, so the adaptive
+// validator selection in validator_selection.go has history to act on.
+func (s *SignatureAggregator) recordOutcome(nodeID ids.NodeID, rtt time.Duration, success bool) {
+	s.validatorScores.RecordResult(nodeID, rtt, success)
+	if s.networkMetrics != nil {
+		s.networkMetrics.ObserveValidatorAppRequest(nodeID.String(), float64(rtt.Milliseconds()), success)
+	}
+}
+
+// recordOutcomes calls recordOutcome for every node ID still in pending, for
+// validators that never responded before the round's deadline.
+func (s *SignatureAggregator) recordOutcomes(pending set.Set[ids.NodeID], rtt time.Duration, success bool) {
+	for nodeID := range pending {
+		s.recordOutcome(nodeID, rtt, success)
+	}
+}
+
+func (s *SignatureAggregator) cacheShare(unsignedMessage *warp.UnsignedMessage, pubKeyBytes []byte, sig []byte) {
+	if s.signatureCache == nil {
+		return
+	}
+	s.signatureCache.Put(SignatureCacheKey{
+		UnsignedMessageID:  unsignedMessage.ID(),
+		ValidatorPublicKey: hex.EncodeToString(pubKeyBytes),
+	}, sig)
+}
+
+// aggregateWeightedSignature aggregates whatever signature shares have been
+// collected into a warp.Message, if they meet quorumPercentage of
+// connectedValidators' total weight.
+func (s *SignatureAggregator) aggregateWeightedSignature(
+	unsignedMessage *warp.UnsignedMessage,
+	connectedValidators *peers.ConnectedCanonicalValidators,
+	quorumPercentage uint64,
+	shares map[ids.NodeID][]byte,
+) (*warp.Message, error) {
+	signersBitSet := set.NewBits()
+	var sigs []*bls.Signature
+	var collectedWeight uint64
+
+	for i, validator := range connectedValidators.ValidatorSet {
+		sigBytes, ok := firstShareFor(shares, validator.NodeIDs)
+		if !ok {
+			continue
+		}
+		sig, err := bls.SignatureFromBytes(sigBytes)
+		if err != nil {
+			continue
+		}
+		signersBitSet.Add(i)
+		sigs = append(sigs, sig)
+		collectedWeight += validator.Weight
+	}
+
+	if len(sigs) == 0 {
+		return nil, errNotEnoughSignatures
+	}
+	if collectedWeight*warpQuorumDenominatorAgg < connectedValidators.TotalValidatorWeight*quorumPercentage {
+		return nil, errNotEnoughSignatures
+	}
+
+	aggSig, err := bls.AggregateSignatures(sigs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate signatures: %w", err)
+	}
+
+	warpSignature := &warp.BitSetSignature{
+		Signers:   signersBitSet.Bytes(),
+		Signature: [bls.SignatureLen]byte(bls.SignatureToBytes(aggSig)),
+	}
+
+	return warp.NewMessage(unsignedMessage, warpSignature)
+}
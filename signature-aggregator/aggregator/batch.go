@@ -0,0 +1,180 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/hashing"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+// UnsignedMessageInput pairs an unsigned warp message with the justification
+// the validators need in order to sign it. A slice of these is submitted
+// together to CreateSignedMessages so that concurrent callers requesting the
+// same (message, quorum, justification) share a single aggregation.
+type UnsignedMessageInput struct {
+	Message       *warp.UnsignedMessage
+	Justification []byte
+}
+
+// batchInflight lets concurrent callers that request the exact same message,
+// quorum, and justification share a single outstanding aggregation instead
+// of each triggering their own round of validator requests.
+type batchInflight struct {
+	wg     sync.WaitGroup
+	result *warp.Message
+	err    error
+}
+
+var errEmptyBatchMessage = errors.New("batch message must not be nil")
+
+// CreateSignedMessages aggregates signatures for every message in msgs,
+// coalescing concurrent callers that request the exact same (message,
+// quorum, justification) into a single aggregation.
+//
+// Unlike a true wire-level batch, each message in msgs still runs its own
+// round of per-validator AppRequests (i.e. this costs the same number of P2P
+// round trips as calling CreateSignedMessage once per message): validators
+// only understand the existing single-message sdk.SignatureRequest proto, so
+// there is no wire format today that lets one AppRequest carry more than one
+// message's hash. What batching still buys a caller is de-duplication of
+// concurrent identical requests and a single call site for the results.
+//
+// signingSubnetID is used only if it can't be resolved from msgs[0]'s source
+// chain (see resolveSigningSubnet); every message in the batch is expected
+// to share the same signing subnet.
+//
+// Quorum is evaluated independently per message: a message that fails to
+// reach quorum does not prevent the others in the batch from succeeding. The
+// returned slices are indexed identically to msgs.
+func (s *SignatureAggregator) CreateSignedMessages(
+	msgs []UnsignedMessageInput,
+	signingSubnetID ids.ID,
+	quorumPercentage uint64,
+) ([]*warp.Message, []error) {
+	results := make([]*warp.Message, len(msgs))
+	errs := make([]error, len(msgs))
+	if len(msgs) == 0 {
+		return results, errs
+	}
+
+	for i, msg := range msgs {
+		if msg.Message == nil {
+			errs[i] = errEmptyBatchMessage
+		}
+	}
+
+	// Like CreateSignedMessageForCaller, resolve the signing subnet from a
+	// message's source chain, falling back to the caller-supplied
+	// signingSubnetID only if that lookup fails; every message in the batch
+	// is expected to share the same signing subnet, so the first message's
+	// source chain is representative.
+	var firstMsg *warp.UnsignedMessage
+	for _, msg := range msgs {
+		if msg.Message != nil {
+			firstMsg = msg.Message
+			break
+		}
+	}
+	if firstMsg == nil {
+		// Every message in the batch was nil; errs is already fully
+		// populated with errEmptyBatchMessage above.
+		return results, errs
+	}
+	resolvedSubnetID, err := s.resolveSigningSubnet(firstMsg, signingSubnetID)
+	if err != nil {
+		for i := range errs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return results, errs
+	}
+	signingSubnetID = resolvedSubnetID
+
+	keys := make([]string, len(msgs))
+	owned := make(map[string]UnsignedMessageInput)
+	entries := make(map[string]*batchInflight, len(msgs))
+
+	s.batchMu.Lock()
+	for i, msg := range msgs {
+		if msg.Message == nil {
+			continue
+		}
+		key := batchCoalesceKey(msg.Message, quorumPercentage, msg.Justification)
+		keys[i] = key
+		if _, seen := entries[key]; seen {
+			continue
+		}
+		if existing, ok := s.inflightBatches[key]; ok {
+			entries[key] = existing
+			continue
+		}
+		entry := &batchInflight{}
+		entry.wg.Add(1)
+		entries[key] = entry
+		owned[key] = msg
+		s.inflightBatches[key] = entry
+	}
+	s.batchMu.Unlock()
+
+	if len(owned) > 0 {
+		s.resolveBatch(owned, entries, signingSubnetID, quorumPercentage)
+	}
+
+	for i, key := range keys {
+		if msgs[i].Message == nil {
+			continue
+		}
+		entry := entries[key]
+		entry.wg.Wait()
+		results[i] = entry.result
+		errs[i] = entry.err
+	}
+	return results, errs
+}
+
+// resolveBatch drives aggregation for the subset of a batch that this caller
+// is responsible for (i.e. wasn't already in flight from a concurrent
+// caller), then publishes the outcome to every coalesced waiter. Each owned
+// message is aggregated concurrently via the same single-message path
+// CreateSignedMessage uses, since there's no wire format today for coalescing
+// multiple messages into one AppRequest (see CreateSignedMessages' doc
+// comment).
+func (s *SignatureAggregator) resolveBatch(
+	owned map[string]UnsignedMessageInput,
+	entries map[string]*batchInflight,
+	signingSubnetID ids.ID,
+	quorumPercentage uint64,
+) {
+	defer func() {
+		s.batchMu.Lock()
+		for key := range owned {
+			delete(s.inflightBatches, key)
+		}
+		s.batchMu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for key, msg := range owned {
+		wg.Add(1)
+		go func(key string, msg UnsignedMessageInput) {
+			defer wg.Done()
+			entry := entries[key]
+			entry.result, entry.err = s.aggregateForSubnet(msg.Message, msg.Justification, signingSubnetID, quorumPercentage, nil)
+			entry.wg.Done()
+		}(key, msg)
+	}
+	wg.Wait()
+}
+
+func batchCoalesceKey(msg *warp.UnsignedMessage, quorumPercentage uint64, justification []byte) string {
+	msgHash := hashing.ComputeHash256(msg.Bytes())
+	justificationHash := hashing.ComputeHash256(justification)
+	return string(msgHash) + ":" + strconv.FormatUint(quorumPercentage, 10) + ":" + string(justificationHash)
+}
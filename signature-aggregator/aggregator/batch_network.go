@@ -0,0 +1,22 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// validatorShares holds the raw BLS signature shares collected from
+// validators for a single message within a batch, indexed the same way
+// aggregateWeightedSignature indexes shares for a non-batched request.
+type validatorShares map[ids.NodeID][]byte
+
+func firstShareFor(shares validatorShares, nodeIDs []ids.NodeID) ([]byte, bool) {
+	for _, nodeID := range nodeIDs {
+		if sig, ok := shares[nodeID]; ok {
+			return sig, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/message"
+	"github.com/ava-labs/avalanchego/proto/pb/sdk"
+	"github.com/ava-labs/avalanchego/subnets"
+	"github.com/ava-labs/avalanchego/utils"
+	"github.com/ava-labs/avalanchego/utils/constants"
+	"github.com/ava-labs/avalanchego/utils/crypto/bls"
+	"github.com/ava-labs/avalanchego/utils/set"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestBatchCoalesceKeyDiffersByQuorumAndJustification(t *testing.T) {
+	msg, err := warp.NewUnsignedMessage(constants.UnitTestID, ids.GenerateTestID(), []byte("payload"))
+	require.NoError(t, err)
+
+	base := batchCoalesceKey(msg, 80, nil)
+	require.Equal(t, base, batchCoalesceKey(msg, 80, nil), "same inputs must produce the same key")
+	require.NotEqual(t, base, batchCoalesceKey(msg, 67, nil), "differing quorum must not collide")
+	require.NotEqual(t, base, batchCoalesceKey(msg, 80, []byte("justification")), "differing justification must not collide")
+}
+
+func TestCreateSignedMessagesCoalescesIdenticalRequests(t *testing.T) {
+	var msg *warp.UnsignedMessage
+	chainID := ids.GenerateTestID()
+	networkID := constants.UnitTestID
+	msg, err := warp.NewUnsignedMessage(networkID, chainID, utils.RandomBytes(128))
+	require.NoError(t, err)
+
+	connectedValidators, validatorSecretKeys := makeConnectedValidators(5)
+
+	aggregator, mockNetwork := instantiateAggregator(t)
+
+	subnetID := ids.GenerateTestID()
+	mockNetwork.EXPECT().GetSubnetID(chainID).Return(subnetID, nil)
+	mockNetwork.EXPECT().TrackSubnet(subnetID)
+	mockNetwork.EXPECT().GetConnectedCanonicalValidators(subnetID).Return(connectedValidators, nil)
+
+	requestID := aggregator.currentRequestID.Load() + 1
+	appRequests := makeAppRequests(chainID, requestID, connectedValidators)
+	for _, appRequest := range appRequests {
+		mockNetwork.EXPECT().RegisterAppRequest(appRequest).Times(1)
+	}
+
+	var nodeIDs set.Set[ids.NodeID]
+	responseChan := make(chan message.InboundMessage, len(appRequests))
+	for _, appRequest := range appRequests {
+		nodeIDs.Add(appRequest.NodeID)
+		validatorSecretKey := validatorSecretKeys[connectedValidators.NodeValidatorIndexMap[appRequest.NodeID]]
+		responseBytes, err := proto.Marshal(
+			&sdk.SignatureResponse{
+				Signature: bls.SignatureToBytes(bls.Sign(validatorSecretKey, msg.Bytes())),
+			},
+		)
+		require.NoError(t, err)
+		responseChan <- message.InboundAppResponse(chainID, requestID, responseBytes, appRequest.NodeID)
+	}
+	mockNetwork.EXPECT().RegisterRequestID(requestID, len(appRequests)).Return(responseChan).Times(1)
+	mockNetwork.EXPECT().Send(gomock.Any(), nodeIDs, subnetID, subnets.NoOpAllower).Times(1).Return(nodeIDs)
+
+	// Two identical inputs in the same batch must coalesce into a single
+	// round of validator requests rather than issuing it twice.
+	inputs := []UnsignedMessageInput{
+		{Message: msg},
+		{Message: msg},
+	}
+	results, errs := aggregator.CreateSignedMessages(inputs, subnetID, 80)
+	require.NoError(t, errs[0])
+	require.NoError(t, errs[1])
+	require.NotNil(t, results[0])
+	require.Same(t, results[0], results[1])
+}
+
+func TestCreateSignedMessagesReportsErrorForNilMessage(t *testing.T) {
+	aggregator, _ := instantiateAggregator(t)
+
+	results, errs := aggregator.CreateSignedMessages([]UnsignedMessageInput{{Message: nil}}, ids.Empty, 80)
+	require.ErrorIs(t, errs[0], errEmptyBatchMessage)
+	require.Nil(t, results[0])
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"time"
+
+	"github.com/ava-labs/avalanchego/cache"
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// SignatureCacheKey identifies a single entry in a SignatureCache backend.
+// Namespace partitions unrelated uses of the same backend (e.g. BLS
+// signature shares versus adaptive validator scores) so their keys can
+// never collide; it is empty for the original signature-share usage.
+// UnsignedMessageID and ValidatorPublicKey are only meaningful within the
+// signature-share namespace.
+type SignatureCacheKey struct {
+	Namespace          string
+	UnsignedMessageID  ids.ID
+	ValidatorPublicKey string
+}
+
+// Bytes returns a deterministic byte encoding of the key, for backends (e.g.
+// BadgerDB, Redis) that only understand raw keys.
+func (k SignatureCacheKey) Bytes() []byte {
+	buf := make([]byte, 0, len(k.Namespace)+1+len(k.UnsignedMessageID)+len(k.ValidatorPublicKey))
+	buf = append(buf, []byte(k.Namespace)...)
+	buf = append(buf, 0) // separator: Namespace is variable-length, so this must be unambiguous
+	buf = append(buf, k.UnsignedMessageID[:]...)
+	buf = append(buf, []byte(k.ValidatorPublicKey)...)
+	return buf
+}
+
+// SignatureCache persists BLS signature shares the aggregator has already
+// collected, keyed by (unsignedMessageID, validatorPublicKey), so that a
+// restarted process doesn't have to re-query the entire validator set for
+// messages it has already partially or fully aggregated.
+//
+// Implementations must be safe for concurrent use.
+type SignatureCache interface {
+	// Get returns the cached signature bytes for key, if present and not
+	// expired.
+	Get(key SignatureCacheKey) ([]byte, bool)
+	// Put stores sig under key, subject to the backend's TTL and max-size
+	// bounds.
+	Put(key SignatureCacheKey, sig []byte)
+	// Close releases any resources held by the backend.
+	Close() error
+}
+
+// signatureCacheTTL bounds how long a cached share is trusted once a
+// validator's BLS key could plausibly have been rotated off the validator
+// set. It is intentionally generous since stale shares are harmless: a share
+// for a node no longer in the validator set is simply never read back.
+const signatureCacheTTL = 24 * time.Hour
+
+// inMemorySignatureCache is the original, pre-persistence cache behavior:
+// an LRU bounded purely by entry count, with no durability across restarts.
+type inMemorySignatureCache struct {
+	cache cache.Cacher[SignatureCacheKey, []byte]
+}
+
+// NewInMemorySignatureCache returns a SignatureCache backed by a bounded
+// in-process LRU. This is the default backend and requires no configuration.
+func NewInMemorySignatureCache(maxSize int) SignatureCache {
+	return &inMemorySignatureCache{
+		cache: &cache.LRU[SignatureCacheKey, []byte]{Size: maxSize},
+	}
+}
+
+func (c *inMemorySignatureCache) Get(key SignatureCacheKey) ([]byte, bool) {
+	return c.cache.Get(key)
+}
+
+func (c *inMemorySignatureCache) Put(key SignatureCacheKey, sig []byte) {
+	c.cache.Put(key, sig)
+}
+
+func (c *inMemorySignatureCache) Close() error {
+	return nil
+}
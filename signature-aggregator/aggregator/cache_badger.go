@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	badger "github.com/dgraph-io/badger/v4"
+	"go.uber.org/zap"
+)
+
+// badgerSignatureCache persists signature shares to an on-disk BadgerDB
+// instance, so that a restarted aggregator doesn't have to re-query the
+// validator set for messages it has already collected shares for.
+type badgerSignatureCache struct {
+	db     *badger.DB
+	logger logging.Logger
+}
+
+// NewBadgerSignatureCache opens (or creates) a BadgerDB at dir and returns a
+// SignatureCache backed by it.
+func NewBadgerSignatureCache(dir string, logger logging.Logger) (SignatureCache, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open badger signature cache at %q: %w", dir, err)
+	}
+	return &badgerSignatureCache{db: db, logger: logger}, nil
+}
+
+func (c *badgerSignatureCache) Get(key SignatureCacheKey) ([]byte, bool) {
+	var sig []byte
+	err := c.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key.Bytes())
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			sig = append([]byte(nil), val...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, false
+	}
+	return sig, true
+}
+
+func (c *badgerSignatureCache) Put(key SignatureCacheKey, sig []byte) {
+	err := c.db.Update(func(txn *badger.Txn) error {
+		entry := badger.NewEntry(key.Bytes(), sig).WithTTL(signatureCacheTTL)
+		return txn.SetEntry(entry)
+	})
+	if err != nil {
+		c.logger.Error("Failed to persist signature share to badger cache", zap.Error(err))
+	}
+}
+
+func (c *badgerSignatureCache) Close() error {
+	return c.db.Close()
+}
+
+// WarmUp iterates every key already on disk so BadgerDB's block cache is
+// primed before the aggregator starts serving requests, rather than paying
+// cold-read latency on the first validator it happens to look up. It
+// returns the number of entries found.
+func (c *badgerSignatureCache) WarmUp() (int, error) {
+	count := 0
+	err := c.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false
+		it := txn.NewIterator(opts)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/icm-services/signature-aggregator/config"
+	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
+)
+
+// NewSignatureCache builds the SignatureCache backend selected by cfg,
+// wrapping it with hit/miss counters registered against cacheMetrics.
+func NewSignatureCache(
+	cfg config.SignatureCacheConfig,
+	cacheMetrics *metrics.SignatureCacheMetrics,
+	logger logging.Logger,
+) (SignatureCache, error) {
+	var (
+		backend SignatureCache
+		err     error
+	)
+	switch cfg.Backend {
+	case "", config.SignatureCacheBackendMemory:
+		backend = NewInMemorySignatureCache(cfg.MaxSize)
+	case config.SignatureCacheBackendBadger:
+		backend, err = NewBadgerSignatureCache(cfg.BadgerDBPath, logger)
+	case config.SignatureCacheBackendRedis:
+		backend, err = NewRedisSignatureCache(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB, logger)
+	default:
+		return nil, fmt.Errorf("unrecognized signature-cache backend %q", cfg.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedSignatureCache{backend: backend, metrics: cacheMetrics}, nil
+}
+
+// instrumentedSignatureCache wraps a SignatureCache backend with Prometheus
+// hit/miss counters, independent of which backend is selected.
+type instrumentedSignatureCache struct {
+	backend SignatureCache
+	metrics *metrics.SignatureCacheMetrics
+}
+
+func (c *instrumentedSignatureCache) Get(key SignatureCacheKey) ([]byte, bool) {
+	sig, ok := c.backend.Get(key)
+	if ok {
+		c.metrics.Hits.Inc()
+	} else {
+		c.metrics.Misses.Inc()
+	}
+	return sig, ok
+}
+
+func (c *instrumentedSignatureCache) Put(key SignatureCacheKey, sig []byte) {
+	c.backend.Put(key, sig)
+}
+
+func (c *instrumentedSignatureCache) Close() error {
+	return c.backend.Close()
+}
+
+// warmableSignatureCache is implemented by backends (currently just
+// badgerSignatureCache) that can proactively load their persisted entries
+// before the aggregator starts serving traffic.
+type warmableSignatureCache interface {
+	WarmUp() (int, error)
+}
+
+// WarmUp forces the underlying backend to load its persisted state, if it
+// supports doing so; backends with nothing to preload (in-memory) or for
+// which a full scan isn't appropriate (redis) are a no-op.
+func (c *instrumentedSignatureCache) WarmUp() (int, error) {
+	if w, ok := c.backend.(warmableSignatureCache); ok {
+		return w.WarmUp()
+	}
+	return 0, nil
+}
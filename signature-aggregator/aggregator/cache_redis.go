@@ -0,0 +1,53 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// redisSignatureCache persists signature shares to a Redis instance, shared
+// across aggregator replicas, so restarting (or replacing) any one instance
+// doesn't lose BLS shares already collected.
+type redisSignatureCache struct {
+	client *redis.Client
+	logger logging.Logger
+}
+
+// NewRedisSignatureCache connects to the Redis instance at addr and returns a
+// SignatureCache backed by it.
+func NewRedisSignatureCache(addr, password string, db int, logger logging.Logger) (SignatureCache, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: password,
+		DB:       db,
+	})
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis signature cache at %q: %w", addr, err)
+	}
+	return &redisSignatureCache{client: client, logger: logger}, nil
+}
+
+func (c *redisSignatureCache) Get(key SignatureCacheKey) ([]byte, bool) {
+	sig, err := c.client.Get(context.Background(), string(key.Bytes())).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return sig, true
+}
+
+func (c *redisSignatureCache) Put(key SignatureCacheKey, sig []byte) {
+	if err := c.client.Set(context.Background(), string(key.Bytes()), sig, signatureCacheTTL).Err(); err != nil {
+		c.logger.Error("Failed to persist signature share to redis cache", zap.Error(err))
+	}
+}
+
+func (c *redisSignatureCache) Close() error {
+	return c.client.Close()
+}
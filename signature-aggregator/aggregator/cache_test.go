@@ -0,0 +1,60 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/icm-services/signature-aggregator/config"
+	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCacheMetrics(t *testing.T) *metrics.SignatureCacheMetrics {
+	m, err := metrics.NewSignatureCacheMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+	return m
+}
+
+func TestNewSignatureCacheDefaultsToMemoryBackend(t *testing.T) {
+	cache, err := NewSignatureCache(config.SignatureCacheConfig{MaxSize: 8}, newTestCacheMetrics(t), logging.NoLog{})
+	require.NoError(t, err)
+	defer cache.Close()
+
+	key := SignatureCacheKey{UnsignedMessageID: ids.GenerateTestID(), ValidatorPublicKey: "pubkey"}
+	_, ok := cache.Get(key)
+	require.False(t, ok)
+
+	cache.Put(key, []byte("sig"))
+	sig, ok := cache.Get(key)
+	require.True(t, ok)
+	require.Equal(t, []byte("sig"), sig)
+}
+
+func TestNewSignatureCacheRejectsUnrecognizedBackend(t *testing.T) {
+	_, err := NewSignatureCache(config.SignatureCacheConfig{Backend: "memcached"}, newTestCacheMetrics(t), logging.NoLog{})
+	require.ErrorContains(t, err, "unrecognized")
+}
+
+func TestInstrumentedSignatureCacheRecordsHitsAndMisses(t *testing.T) {
+	cacheMetrics := newTestCacheMetrics(t)
+	cache, err := NewSignatureCache(config.SignatureCacheConfig{MaxSize: 8}, cacheMetrics, logging.NoLog{})
+	require.NoError(t, err)
+	defer cache.Close()
+
+	key := SignatureCacheKey{UnsignedMessageID: ids.GenerateTestID(), ValidatorPublicKey: "pubkey"}
+
+	_, ok := cache.Get(key)
+	require.False(t, ok)
+	require.Equal(t, float64(1), testutil.ToFloat64(cacheMetrics.Misses))
+
+	cache.Put(key, []byte("sig"))
+	_, ok = cache.Get(key)
+	require.True(t, ok)
+	require.Equal(t, float64(1), testutil.ToFloat64(cacheMetrics.Hits))
+}
@@ -0,0 +1,312 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
+)
+
+// ErrJobDeadlineExceeded is returned to a caller whose job was still queued
+// when its deadline elapsed, rather than leaving it to run arbitrarily late.
+var ErrJobDeadlineExceeded = errors.New("message queue job exceeded its deadline while queued")
+
+// ewmaHalfLife controls how quickly a caller's tracked usage decays, so that
+// a caller that was heavy a minute ago but has since gone quiet doesn't stay
+// penalized forever. This mirrors the decay avalanchego's handler CPU
+// tracker applies to validator usage.
+const ewmaHalfLife = 5 * time.Second
+
+// callerUsageTracker maintains an EWMA of wall-time usage per caller (remote
+// IP or API key), used to pick the caller with the smallest decayed usage
+// when two jobs are otherwise eligible to run.
+type callerUsageTracker struct {
+	mu         sync.Mutex
+	usage      map[string]float64
+	lastUpdate map[string]time.Time
+}
+
+func newCallerUsageTracker() *callerUsageTracker {
+	return &callerUsageTracker{
+		usage:      make(map[string]float64),
+		lastUpdate: make(map[string]time.Time),
+	}
+}
+
+// Record adds duration of usage for caller, decaying its prior usage based
+// on elapsed wall-clock time first.
+func (t *callerUsageTracker) Record(caller string, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.decayLocked(caller)
+	t.usage[caller] += duration.Seconds()
+	t.lastUpdate[caller] = time.Now()
+}
+
+// Usage returns caller's current decayed usage. It applies the decay for
+// elapsed time to the stored value first, as Record does.
+func (t *callerUsageTracker) Usage(caller string) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.decayLocked(caller)
+	return t.usage[caller]
+}
+
+func (t *callerUsageTracker) decayLocked(caller string) {
+	last, ok := t.lastUpdate[caller]
+	if !ok {
+		t.lastUpdate[caller] = time.Now()
+		return
+	}
+	elapsed := time.Since(last)
+	if elapsed <= 0 {
+		return
+	}
+	halfLives := float64(elapsed) / float64(ewmaHalfLife)
+	t.usage[caller] *= math.Exp2(-halfLives)
+	t.lastUpdate[caller] = time.Now()
+}
+
+// queueJob is a pending CreateSignedMessage call waiting to be scheduled.
+type queueJob struct {
+	subnetID    ids.ID
+	callerKey   string
+	deadline    time.Time
+	submittedAt time.Time
+	run         func() (*warp.Message, error)
+	resultCh    chan queueJobResult
+}
+
+type queueJobResult struct {
+	msg *warp.Message
+	err error
+}
+
+// subnetQueue holds the pending jobs and configured stake weight for a
+// single subnet.
+type subnetQueue struct {
+	stakeWeight uint64
+	inFlight    int
+	jobs        []*queueJob
+}
+
+// MessageQueue schedules CreateSignedMessage jobs across subnets, giving
+// each subnet a share of aggregation concurrency proportional to its total
+// validator stake, and within a subnet preferring the caller (remote IP or
+// API key) with the least recent usage so that one slow or heavy caller
+// cannot starve the others. This mirrors the validator-fair dequeue used by
+// avalanchego's handler message queue.
+type MessageQueue struct {
+	mu        sync.Mutex
+	cond      *sync.Cond
+	subnets   map[ids.ID]*subnetQueue
+	usage     *callerUsageTracker
+	metrics   *metrics.MessageQueueMetrics
+	workers   int
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewMessageQueue starts a MessageQueue with the given worker concurrency
+// (the maximum number of jobs running at once across all subnets).
+func NewMessageQueue(workers int, queueMetrics *metrics.MessageQueueMetrics) *MessageQueue {
+	q := &MessageQueue{
+		subnets: make(map[ids.ID]*subnetQueue),
+		usage:   newCallerUsageTracker(),
+		metrics: queueMetrics,
+		workers: workers,
+		closed:  make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	for i := 0; i < workers; i++ {
+		go q.workerLoop()
+	}
+	return q
+}
+
+// SetSubnetStakeWeight updates the stake weight used to compute subnetID's
+// fair share of concurrency. It should be called whenever the tracked
+// validator set for a subnet changes.
+func (q *MessageQueue) SetSubnetStakeWeight(subnetID ids.ID, stakeWeight uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	sq := q.subnetQueueLocked(subnetID)
+	sq.stakeWeight = stakeWeight
+}
+
+func (q *MessageQueue) subnetQueueLocked(subnetID ids.ID) *subnetQueue {
+	sq, ok := q.subnets[subnetID]
+	if !ok {
+		sq = &subnetQueue{stakeWeight: 1}
+		q.subnets[subnetID] = sq
+	}
+	return sq
+}
+
+// Submit enqueues run to be executed under subnetID's fair share of
+// concurrency, attributed to callerKey for fairness accounting, and blocks
+// until it completes or is dropped for exceeding deadline. If the queue is
+// closed before the job runs, Submit does not return (see Close).
+func (q *MessageQueue) Submit(
+	subnetID ids.ID,
+	callerKey string,
+	deadline time.Time,
+	run func() (*warp.Message, error),
+) (*warp.Message, error) {
+	job := &queueJob{
+		subnetID:    subnetID,
+		callerKey:   callerKey,
+		deadline:    deadline,
+		submittedAt: time.Now(),
+		run:         run,
+		resultCh:    make(chan queueJobResult, 1),
+	}
+
+	q.mu.Lock()
+	sq := q.subnetQueueLocked(subnetID)
+	sq.jobs = append(sq.jobs, job)
+	if q.metrics != nil {
+		q.metrics.QueueDepth.WithLabelValues(subnetID.String()).Inc()
+	}
+	q.cond.Signal()
+	q.mu.Unlock()
+
+	result := <-job.resultCh
+	return result.msg, result.err
+}
+
+func (q *MessageQueue) workerLoop() {
+	for {
+		job, ok := q.dequeue()
+		if !ok {
+			return
+		}
+
+		subnetLabel := job.subnetID.String()
+		if q.metrics != nil {
+			q.metrics.QueueDepth.WithLabelValues(subnetLabel).Dec()
+			q.metrics.WaitTimeMS.WithLabelValues(subnetLabel).Observe(float64(time.Since(job.submittedAt).Milliseconds()))
+		}
+
+		if !job.deadline.IsZero() && time.Now().After(job.deadline) {
+			if q.metrics != nil {
+				q.metrics.DroppedJobs.WithLabelValues(subnetLabel).Inc()
+			}
+			job.resultCh <- queueJobResult{err: ErrJobDeadlineExceeded}
+			q.finishJob(job)
+			continue
+		}
+
+		if q.metrics != nil {
+			q.metrics.InFlight.WithLabelValues(subnetLabel).Inc()
+		}
+		start := time.Now()
+		msg, err := job.run()
+		q.usage.Record(job.callerKey, time.Since(start))
+		if q.metrics != nil {
+			q.metrics.InFlight.WithLabelValues(subnetLabel).Dec()
+		}
+
+		job.resultCh <- queueJobResult{msg: msg, err: err}
+		q.finishJob(job)
+	}
+}
+
+func (q *MessageQueue) finishJob(job *queueJob) {
+	q.mu.Lock()
+	if sq, ok := q.subnets[job.subnetID]; ok {
+		sq.inFlight--
+	}
+	q.mu.Unlock()
+}
+
+// dequeue picks the next job to run: first the subnet furthest below its
+// stake-weighted fair share of the worker pool, then within that
+// subnet the job belonging to the caller with the smallest recent usage.
+func (q *MessageQueue) dequeue() (*queueJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for {
+		select {
+		case <-q.closed:
+			return nil, false
+		default:
+		}
+
+		job, sq := q.pickNextLocked()
+		if job != nil {
+			q.removeJobLocked(sq, job)
+			sq.inFlight++
+			return job, true
+		}
+		q.cond.Wait()
+	}
+}
+
+func (q *MessageQueue) pickNextLocked() (*queueJob, *subnetQueue) {
+	var totalStake uint64
+	for _, sq := range q.subnets {
+		totalStake += sq.stakeWeight
+	}
+	if totalStake == 0 {
+		return nil, nil
+	}
+
+	var (
+		bestSubnet  *subnetQueue
+		bestDeficit = math.Inf(-1)
+	)
+	for _, sq := range q.subnets {
+		if len(sq.jobs) == 0 {
+			continue
+		}
+		fairShare := float64(sq.stakeWeight) / float64(totalStake)
+		deficit := fairShare*float64(q.workers) - float64(sq.inFlight)
+		if deficit > bestDeficit {
+			bestDeficit = deficit
+			bestSubnet = sq
+		}
+	}
+	if bestSubnet == nil {
+		return nil, nil
+	}
+
+	bestJobIdx := 0
+	bestUsage := q.usage.Usage(bestSubnet.jobs[0].callerKey)
+	for i, job := range bestSubnet.jobs {
+		usage := q.usage.Usage(job.callerKey)
+		if usage < bestUsage {
+			bestUsage = usage
+			bestJobIdx = i
+		}
+	}
+	return bestSubnet.jobs[bestJobIdx], bestSubnet
+}
+
+func (q *MessageQueue) removeJobLocked(sq *subnetQueue, job *queueJob) {
+	for i, j := range sq.jobs {
+		if j == job {
+			sq.jobs = append(sq.jobs[:i], sq.jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close stops all worker goroutines. Jobs still queued will never receive a
+// result.
+func (q *MessageQueue) Close() {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+		q.mu.Lock()
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	})
+}
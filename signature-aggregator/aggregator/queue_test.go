@@ -0,0 +1,103 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMessageQueue(t *testing.T, workers int) *MessageQueue {
+	queueMetrics, err := metrics.NewMessageQueueMetrics(prometheus.NewRegistry())
+	require.NoError(t, err)
+	q := NewMessageQueue(workers, queueMetrics)
+	t.Cleanup(q.Close)
+	return q
+}
+
+func TestMessageQueueRunsSubmittedJob(t *testing.T) {
+	q := newTestMessageQueue(t, 1)
+	subnetID := ids.GenerateTestID()
+	q.SetSubnetStakeWeight(subnetID, 1)
+
+	msg, err := q.Submit(subnetID, "caller", time.Time{}, func() (*warp.Message, error) {
+		return &warp.Message{}, nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, msg)
+}
+
+func TestMessageQueueDropsJobsPastDeadline(t *testing.T) {
+	q := newTestMessageQueue(t, 1)
+	subnetID := ids.GenerateTestID()
+	q.SetSubnetStakeWeight(subnetID, 1)
+
+	// Block the single worker so the next job is still queued once its
+	// deadline has already elapsed.
+	blocker := make(chan struct{})
+	go q.Submit(subnetID, "blocker", time.Time{}, func() (*warp.Message, error) {
+		<-blocker
+		return nil, nil
+	})
+	time.Sleep(10 * time.Millisecond) // let the blocker job claim the only worker
+
+	// Release the worker only after the job below has been queued, so it is
+	// dequeued once its deadline has already passed.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		close(blocker)
+	}()
+
+	_, err := q.Submit(subnetID, "caller", time.Now().Add(-time.Second), func() (*warp.Message, error) {
+		t.Fatal("job past its deadline must not run")
+		return nil, nil
+	})
+	require.ErrorIs(t, err, ErrJobDeadlineExceeded)
+}
+
+func TestCallerUsageTrackerRanksHeavierCallerAboveLighter(t *testing.T) {
+	usage := newCallerUsageTracker()
+	usage.Record("heavy", time.Second)
+	usage.Record("light", time.Millisecond)
+
+	require.Greater(t, usage.Usage("heavy"), usage.Usage("light"))
+}
+
+func TestCallerUsageTrackerDecaysOverTime(t *testing.T) {
+	usage := newCallerUsageTracker()
+	usage.Record("caller", time.Second)
+	before := usage.Usage("caller")
+
+	time.Sleep(2 * ewmaHalfLife)
+	after := usage.Usage("caller")
+	require.Less(t, after, before)
+}
+
+func TestPickNextLockedPrefersSubnetFurthestBelowFairShare(t *testing.T) {
+	q := newTestMessageQueue(t, 4)
+	heavySubnet := ids.GenerateTestID()
+	lightSubnet := ids.GenerateTestID()
+	q.SetSubnetStakeWeight(heavySubnet, 3)
+	q.SetSubnetStakeWeight(lightSubnet, 1)
+
+	q.mu.Lock()
+	heavyJob := &queueJob{subnetID: heavySubnet, callerKey: "c"}
+	lightJob := &queueJob{subnetID: lightSubnet, callerKey: "c"}
+	q.subnetQueueLocked(heavySubnet).jobs = []*queueJob{heavyJob}
+	q.subnetQueueLocked(lightSubnet).jobs = []*queueJob{lightJob}
+	// Give the light subnet all of the current concurrency so its deficit is
+	// lower than the heavy subnet's despite its smaller stake weight.
+	q.subnetQueueLocked(lightSubnet).inFlight = 4
+	job, sq := q.pickNextLocked()
+	q.mu.Unlock()
+
+	require.Same(t, heavyJob, job)
+	require.Equal(t, uint64(3), sq.stakeWeight)
+}
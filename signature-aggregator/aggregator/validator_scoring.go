@@ -0,0 +1,209 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ava-labs/avalanchego/ids"
+)
+
+// rttEWMAHalfLife controls how quickly a validator's tracked round-trip
+// time adapts to recent behavior versus its history.
+const rttEWMAHalfLife = 30 * time.Second
+
+// successWindowSize is the number of recent AppRequests, per validator,
+// used to compute a sliding-window success rate.
+const successWindowSize = 50
+
+// validatorScoreNamespace partitions ValidatorScoreTracker's cache entries
+// from the signature-share entries the same SignatureCache backend stores,
+// so the two unrelated keyspaces can never collide.
+const validatorScoreNamespace = "validator-score"
+
+// ValidatorScore is a validator's current standing in the adaptive
+// selection scoring table: an EWMA of round-trip time and a sliding-window
+// success rate, both derived from historical AppRequest outcomes.
+type ValidatorScore struct {
+	NodeID      ids.NodeID `json:"nodeID"`
+	RTTMillis   float64    `json:"rttMillis"`
+	SuccessRate float64    `json:"successRate"`
+	Samples     int        `json:"samples"`
+}
+
+type validatorScoreState struct {
+	rttMillis   float64
+	lastUpdate  time.Time
+	outcomes    []bool
+	outcomeHead int
+	samples     int
+}
+
+// ValidatorScoreTracker maintains the adaptive selection scoring table
+// described in ValidatorScore, persisting it across restarts via a
+// SignatureCache backend so a bounced aggregator doesn't start every
+// validator back at zero history.
+type ValidatorScoreTracker struct {
+	mu    sync.Mutex
+	state map[ids.NodeID]*validatorScoreState
+	cache SignatureCache
+}
+
+// NewValidatorScoreTracker returns a tracker that persists scores via cache.
+// cache may be nil, in which case scores are kept in memory only.
+func NewValidatorScoreTracker(cache SignatureCache) *ValidatorScoreTracker {
+	return &ValidatorScoreTracker{
+		state: make(map[ids.NodeID]*validatorScoreState),
+		cache: cache,
+	}
+}
+
+// RecordResult updates nodeID's EWMA round-trip time and sliding-window
+// success rate with the outcome of a single AppRequest.
+func (t *ValidatorScoreTracker) RecordResult(nodeID ids.NodeID, rtt time.Duration, success bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.state[nodeID]
+	if !ok {
+		s, ok = t.loadFromCacheLocked(nodeID)
+	}
+	if !ok {
+		s = &validatorScoreState{outcomes: make([]bool, 0, successWindowSize)}
+		t.state[nodeID] = s
+	}
+
+	now := time.Now()
+	if s.lastUpdate.IsZero() {
+		s.rttMillis = float64(rtt.Milliseconds())
+	} else {
+		elapsed := now.Sub(s.lastUpdate)
+		weight := 1 - math.Exp2(-float64(elapsed)/float64(rttEWMAHalfLife))
+		s.rttMillis += weight * (float64(rtt.Milliseconds()) - s.rttMillis)
+	}
+	s.lastUpdate = now
+
+	if len(s.outcomes) < successWindowSize {
+		s.outcomes = append(s.outcomes, success)
+	} else {
+		s.outcomes[s.outcomeHead] = success
+		s.outcomeHead = (s.outcomeHead + 1) % successWindowSize
+	}
+	s.samples++
+
+	t.persistLocked(nodeID, s)
+}
+
+// Score returns nodeID's current score, or the zero value and false if no
+// history has been recorded for it yet.
+func (t *ValidatorScoreTracker) Score(nodeID ids.NodeID) (ValidatorScore, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.state[nodeID]
+	if !ok {
+		s, ok = t.loadFromCacheLocked(nodeID)
+	}
+	if !ok {
+		return ValidatorScore{}, false
+	}
+	return toValidatorScore(nodeID, s), true
+}
+
+// All returns the current score for every validator whose history is held
+// in memory, for use by the /debug/validators endpoint. Persisted scores that
+// have not been looked up since a restart (see loadFromCacheLocked) are not
+// included.
+func (t *ValidatorScoreTracker) All() []ValidatorScore {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	scores := make([]ValidatorScore, 0, len(t.state))
+	for nodeID, s := range t.state {
+		scores = append(scores, toValidatorScore(nodeID, s))
+	}
+	return scores
+}
+
+func toValidatorScore(nodeID ids.NodeID, s *validatorScoreState) ValidatorScore {
+	var successes int
+	for _, ok := range s.outcomes {
+		if ok {
+			successes++
+		}
+	}
+	successRate := 1.0
+	if len(s.outcomes) > 0 {
+		successRate = float64(successes) / float64(len(s.outcomes))
+	}
+	return ValidatorScore{
+		NodeID:      nodeID,
+		RTTMillis:   s.rttMillis,
+		SuccessRate: successRate,
+		Samples:     s.samples,
+	}
+}
+
+// persistedValidatorScoreState is the exact state persistLocked writes and
+// loadFromCacheLocked reads back, distinct from the derived ValidatorScore
+// returned by Score/All, so a restart resumes the sliding window exactly
+// rather than reconstructing it from a lossy summary.
+type persistedValidatorScoreState struct {
+	RTTMillis   float64 `json:"rttMillis"`
+	Outcomes    []bool  `json:"outcomes"`
+	OutcomeHead int     `json:"outcomeHead"`
+	Samples     int     `json:"samples"`
+}
+
+func validatorScoreCacheKey(nodeID ids.NodeID) SignatureCacheKey {
+	return SignatureCacheKey{
+		Namespace:          validatorScoreNamespace,
+		ValidatorPublicKey: nodeID.String(),
+	}
+}
+
+func (t *ValidatorScoreTracker) persistLocked(nodeID ids.NodeID, s *validatorScoreState) {
+	if t.cache == nil {
+		return
+	}
+	encoded, err := json.Marshal(persistedValidatorScoreState{
+		RTTMillis:   s.rttMillis,
+		Outcomes:    s.outcomes,
+		OutcomeHead: s.outcomeHead,
+		Samples:     s.samples,
+	})
+	if err != nil {
+		return
+	}
+	t.cache.Put(validatorScoreCacheKey(nodeID), encoded)
+}
+
+// loadFromCacheLocked lazily restores nodeID's persisted score state into
+// t.state the first time it's looked up after a restart. t.mu must already
+// be held. The SignatureCache interface only supports point lookups (no
+// enumeration), so scores are restored one validator at a time as they're
+// queried rather than all at once at startup.
+func (t *ValidatorScoreTracker) loadFromCacheLocked(nodeID ids.NodeID) (*validatorScoreState, bool) {
+	if t.cache == nil {
+		return nil, false
+	}
+	encoded, ok := t.cache.Get(validatorScoreCacheKey(nodeID))
+	if !ok {
+		return nil, false
+	}
+	var persisted persistedValidatorScoreState
+	if err := json.Unmarshal(encoded, &persisted); err != nil {
+		return nil, false
+	}
+	s := &validatorScoreState{
+		rttMillis:   persisted.RTTMillis,
+		lastUpdate:  time.Now(),
+		outcomes:    persisted.Outcomes,
+		outcomeHead: persisted.OutcomeHead,
+		samples:     persisted.Samples,
+	}
+	t.state[nodeID] = s
+	return s, true
+}
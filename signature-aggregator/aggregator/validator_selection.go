@@ -0,0 +1,124 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"sort"
+
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+)
+
+// targetP95Millis and minSuccessRate bound which validators are eligible
+// for the first round of an adaptive send: only validators with a
+// historically healthy response time and success rate are asked up front.
+const (
+	targetP95Millis = 2000.0
+	minSuccessRate  = 0.8
+)
+
+// fanoutWidenFactor is how much the adaptive fan-out grows each retry round
+// once the first, narrow round fails to reach quorum.
+const fanoutWidenFactor = 2
+
+// SelectValidatorsForRound chooses which of connectedValidators to send an
+// AppRequest to. prevSelectionSize is 0 for the first attempt; on a retry,
+// after a prior round failed to reach quorum, it is the number of
+// validators that round selected, so the widened set can grow relative to
+// what was actually tried rather than restarting from scratch.
+//
+// The first round sends to every validator whose tracked history meets
+// targetP95Millis (compared against its EWMA round-trip time) and
+// minSuccessRate; that set is not trimmed to the smallest stake-weighted
+// subset that would reach quorum. Each subsequent round doubles the
+// previous round's size (see widen). Absent any history (e.g. right after a
+// restart, before scores is warmed), no validator qualifies, so every
+// validator is selected and CreateSignedMessage broadcasts to all of them.
+func SelectValidatorsForRound(
+	validators []*warp.Validator,
+	scores *ValidatorScoreTracker,
+	prevSelectionSize int,
+) []*warp.Validator {
+	if scores == nil {
+		return validators
+	}
+	if prevSelectionSize > 0 {
+		return widen(validators, scores, prevSelectionSize)
+	}
+
+	var healthy []*warp.Validator
+	for _, v := range validators {
+		if isHealthy(v, scores) {
+			healthy = append(healthy, v)
+		}
+	}
+	if len(healthy) == 0 {
+		// No validator has a warm enough history yet; fall back to
+		// broadcasting to everyone rather than stalling the first round.
+		return validators
+	}
+	return healthy
+}
+
+func isHealthy(v *warp.Validator, scores *ValidatorScoreTracker) bool {
+	for _, nodeID := range v.NodeIDs {
+		score, ok := scores.Score(nodeID)
+		if !ok {
+			continue
+		}
+		if score.RTTMillis <= targetP95Millis && score.SuccessRate >= minSuccessRate {
+			return true
+		}
+	}
+	return false
+}
+
+// widen ranks validators by RTT (best first, unscored validators treated as
+// best-effort/unknown and sorted after scored ones) and returns a prefix
+// fanoutWidenFactor times as large as prevSelectionSize, the number of
+// validators the previous round actually selected. This guarantees the
+// fan-out only ever grows on retry, regardless of how small or large that
+// prior selection was.
+func widen(validators []*warp.Validator, scores *ValidatorScoreTracker, prevSelectionSize int) []*warp.Validator {
+	ranked := make([]*warp.Validator, len(validators))
+	copy(ranked, validators)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		ri, iok := bestRTT(ranked[i], scores)
+		rj, jok := bestRTT(ranked[j], scores)
+		if iok != jok {
+			return iok
+		}
+		return ri < rj
+	})
+
+	size := prevSelectionSize * fanoutWidenFactor
+	if size <= prevSelectionSize {
+		// Guard against overflow or a non-positive prevSelectionSize still
+		// widening by at least one validator.
+		size = prevSelectionSize + 1
+	}
+	if size > len(ranked) {
+		size = len(ranked)
+	}
+	return ranked[:size]
+}
+
+func bestRTT(v *warp.Validator, scores *ValidatorScoreTracker) (float64, bool) {
+	if scores == nil {
+		return 0, false
+	}
+	best := -1.0
+	found := false
+	for _, nodeID := range v.NodeIDs {
+		score, ok := scores.Score(nodeID)
+		if !ok {
+			continue
+		}
+		if !found || score.RTTMillis < best {
+			best = score.RTTMillis
+			found = true
+		}
+	}
+	return best, found
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestValidators(n int) []*warp.Validator {
+	validators := make([]*warp.Validator, n)
+	for i := range validators {
+		validators[i] = &warp.Validator{
+			Weight:  1,
+			NodeIDs: []ids.NodeID{ids.GenerateTestNodeID()},
+		}
+	}
+	return validators
+}
+
+func TestSelectValidatorsForRoundNoScores(t *testing.T) {
+	validators := makeTestValidators(10)
+	require.Len(t, SelectValidatorsForRound(validators, nil, 0), 10)
+	require.Len(t, SelectValidatorsForRound(validators, nil, 3), 10)
+}
+
+// TestSelectValidatorsForRoundWidensFromPriorSize ensures that a retry
+// round's fan-out always grows relative to how many validators the prior
+// round actually selected, rather than restarting from a fixed size. This
+// guards against regressing to a widen() that ignores prevSelectionSize and
+// can shrink the fan-out on retry.
+func TestSelectValidatorsForRoundWidensFromPriorSize(t *testing.T) {
+	validators := makeTestValidators(20)
+	scores := NewValidatorScoreTracker(nil)
+
+	round0 := SelectValidatorsForRound(validators, scores, 0)
+	require.Len(t, round0, 20) // no history yet: falls back to everyone
+
+	// With a large prior selection, widening should not shrink below it.
+	round1 := SelectValidatorsForRound(validators, scores, 12)
+	require.Len(t, round1, 20) // 12*2 > 20, so it's clamped to the full set
+
+	round1Small := SelectValidatorsForRound(validators, scores, 3)
+	require.Len(t, round1Small, 6)
+	require.GreaterOrEqual(t, len(round1Small), 3)
+}
+
+func TestWidenNeverShrinks(t *testing.T) {
+	validators := makeTestValidators(50)
+	scores := NewValidatorScoreTracker(nil)
+
+	prevSize := 1
+	for i := 0; i < 6; i++ {
+		selected := widen(validators, scores, prevSize)
+		require.GreaterOrEqual(t, len(selected), prevSize)
+		prevSize = len(selected)
+	}
+	require.Equal(t, 50, prevSize)
+}
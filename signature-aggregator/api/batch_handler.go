@@ -0,0 +1,136 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/icm-services/signature-aggregator/aggregator"
+	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
+	"go.uber.org/zap"
+)
+
+const BatchAggregateSignaturesPath = "/aggregate-signatures/batch"
+
+type batchAggregateSignaturesRequest struct {
+	Messages []struct {
+		Message       string `json:"message"`
+		Justification string `json:"justification,omitempty"`
+	} `json:"messages"`
+	SigningSubnetID  string `json:"signing-subnet-id,omitempty"`
+	QuorumPercentage uint64 `json:"quorum-percentage,omitempty"`
+}
+
+type batchAggregateSignaturesResult struct {
+	SignedMessage string `json:"signed-message,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+type batchAggregateSignaturesResponse struct {
+	Results []batchAggregateSignaturesResult `json:"results"`
+}
+
+// HandleBatchAggregateSignaturesRequest registers the batched aggregation
+// endpoint. Unlike the single-message endpoint, a batch request submits N
+// unsigned messages for the same signing subnet in a single call and returns
+// N results, coalescing concurrent requests for the same (message, quorum,
+// justification) rather than issuing one P2P round per duplicate (see
+// CreateSignedMessages' doc comment).
+func HandleBatchAggregateSignaturesRequest(
+	logger logging.Logger,
+	metricsInstance *metrics.SignatureAggregatorMetrics,
+	signatureAggregator *aggregator.SignatureAggregator,
+) {
+	http.Handle(
+		BatchAggregateSignaturesPath,
+		batchAggregateSignaturesHandler(logger, metricsInstance, signatureAggregator),
+	)
+}
+
+func batchAggregateSignaturesHandler(
+	logger logging.Logger,
+	metricsInstance *metrics.SignatureAggregatorMetrics,
+	signatureAggregator *aggregator.SignatureAggregator,
+) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req batchAggregateSignaturesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var signingSubnetID ids.ID
+		if req.SigningSubnetID != "" {
+			var err error
+			signingSubnetID, err = ids.FromString(req.SigningSubnetID)
+			if err != nil {
+				http.Error(w, "invalid signing-subnet-id: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		quorumPercentage := req.QuorumPercentage
+		if quorumPercentage == 0 {
+			quorumPercentage = warp.WarpDefaultQuorumNumerator
+		}
+
+		inputs := make([]aggregator.UnsignedMessageInput, len(req.Messages))
+		for i, m := range req.Messages {
+			msgBytes, err := hex.DecodeString(m.Message)
+			if err != nil {
+				http.Error(w, "invalid message hex at index "+strconv.Itoa(i)+": "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			unsignedMessage, err := warp.ParseUnsignedMessage(msgBytes)
+			if err != nil {
+				http.Error(w, "invalid unsigned message at index "+strconv.Itoa(i)+": "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			var justification []byte
+			if m.Justification != "" {
+				justification, err = hex.DecodeString(m.Justification)
+				if err != nil {
+					http.Error(w, "invalid justification hex at index "+strconv.Itoa(i)+": "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+
+			inputs[i] = aggregator.UnsignedMessageInput{
+				Message:       unsignedMessage,
+				Justification: justification,
+			}
+		}
+
+		signedMessages, errs := signatureAggregator.CreateSignedMessages(inputs, signingSubnetID, quorumPercentage)
+
+		results := make([]batchAggregateSignaturesResult, len(inputs))
+		for i := range inputs {
+			if errs[i] != nil {
+				logger.Debug("Failed to aggregate signatures for batched message", zap.Int("index", i), zap.Error(errs[i]))
+				results[i] = batchAggregateSignaturesResult{Error: errs[i].Error()}
+				continue
+			}
+			results[i] = batchAggregateSignaturesResult{
+				SignedMessage: hex.EncodeToString(signedMessages[i].Bytes()),
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(batchAggregateSignaturesResponse{Results: results}); err != nil {
+			logger.Error("Failed to write batch aggregation response", zap.Error(err))
+		}
+	})
+}
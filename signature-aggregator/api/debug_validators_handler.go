@@ -0,0 +1,32 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/icm-services/signature-aggregator/aggregator"
+	"go.uber.org/zap"
+)
+
+const DebugValidatorsPath = "/debug/validators"
+
+// HandleDebugValidatorsRequest registers an endpoint dumping the current
+// adaptive validator scoring table: each validator's EWMA round-trip time
+// and sliding-window success rate, as used to drive CreateSignedMessage's
+// adaptive fan-out.
+func HandleDebugValidatorsRequest(logger logging.Logger, scores *aggregator.ValidatorScoreTracker) {
+	http.Handle(DebugValidatorsPath, debugValidatorsHandler(logger, scores))
+}
+
+func debugValidatorsHandler(logger logging.Logger, scores *aggregator.ValidatorScoreTracker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(scores.All()); err != nil {
+			logger.Error("Failed to write debug validators response", zap.Error(err))
+		}
+	})
+}
@@ -0,0 +1,174 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"io"
+
+	"github.com/ava-labs/avalanchego/ids"
+	"github.com/ava-labs/avalanchego/utils/logging"
+	"github.com/ava-labs/avalanchego/vms/platformvm/warp"
+	"github.com/ava-labs/icm-services/signature-aggregator/aggregator"
+	"github.com/ava-labs/icm-services/signature-aggregator/api/proto/pb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// maxInFlightPerStream bounds how many AggregateRequests from a single
+// stream are being aggregated concurrently. Once reached, reads from the
+// stream pause until an in-flight request completes, providing flow control
+// against a slow consumer that isn't draining responses.
+const maxInFlightPerStream = 64
+
+// responseBufferSize gives the responses channel enough slack that a
+// momentary lag in stream.Send (the forwarding goroutine in
+// AggregateSignatures) doesn't immediately force handleRequest's onProgress
+// callback to drop an update; it still drops once the buffer is full rather
+// than block the aggregation goroutine.
+const responseBufferSize = 16
+
+// grpcServer implements pb.AggregatorServer on top of the same
+// SignatureAggregator and network plumbing the HTTP handlers use.
+type grpcServer struct {
+	pb.UnimplementedAggregatorServer
+
+	logger     logging.Logger
+	aggregator *aggregator.SignatureAggregator
+}
+
+// RegisterGRPCServer registers the streaming Aggregator service against
+// grpcServerHandle, backed by signatureAggregator.
+func RegisterGRPCServer(
+	grpcServerHandle *grpc.Server,
+	logger logging.Logger,
+	signatureAggregator *aggregator.SignatureAggregator,
+) {
+	pb.RegisterAggregatorServer(grpcServerHandle, &grpcServer{
+		logger:     logger,
+		aggregator: signatureAggregator,
+	})
+}
+
+func (s *grpcServer) AggregateSignatures(stream pb.Aggregator_AggregateSignaturesServer) error {
+	sem := make(chan struct{}, maxInFlightPerStream)
+	responses := make(chan *pb.AggregateResponse, responseBufferSize)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		for {
+			select {
+			case resp, ok := <-responses:
+				if !ok {
+					return
+				}
+				if err := stream.Send(resp); err != nil {
+					s.logger.Debug("Failed to send aggregate response", zap.Error(err))
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		sem <- struct{}{}
+		go s.handleRequest(req, responses, done, sem)
+	}
+}
+
+func (s *grpcServer) handleRequest(
+	req *pb.AggregateRequest,
+	responses chan<- *pb.AggregateResponse,
+	done <-chan struct{},
+	sem chan struct{},
+) {
+	defer func() { <-sem }()
+
+	unsignedMessage, err := warp.ParseUnsignedMessage(req.UnsignedMessage)
+	if err != nil {
+		sendResponse(responses, done, errorResponse(req.RequestId, "invalid unsigned message: "+err.Error()))
+		return
+	}
+
+	var signingSubnetID ids.ID
+	if req.SigningSubnetId != "" {
+		signingSubnetID, err = ids.FromString(req.SigningSubnetId)
+		if err != nil {
+			sendResponse(responses, done, errorResponse(req.RequestId, "invalid signing subnet id: "+err.Error()))
+			return
+		}
+	}
+
+	signedMessage, err := s.aggregator.CreateSignedMessageWithProgress(
+		unsignedMessage,
+		req.Justification,
+		signingSubnetID,
+		req.QuorumPercentage,
+		func(collectedWeight, totalWeight uint64) {
+			// onProgress is called synchronously from the aggregation
+			// goroutine (see CreateSignedMessageWithProgress's doc comment)
+			// and must not block; a stalled stream.Send on a slow consumer
+			// would otherwise eat into signingRequestTimeout for every
+			// subnet sharing this SignatureAggregator. Drop the update
+			// instead of blocking if the forwarding goroutine is busy —
+			// progress events are advisory and superseded by the next one.
+			select {
+			case responses <- &pb.AggregateResponse{
+				RequestId: req.RequestId,
+				Event: &pb.AggregateResponse_Progress{
+					Progress: &pb.Progress{
+						CollectedWeight: collectedWeight,
+						TotalWeight:     totalWeight,
+					},
+				},
+			}:
+			case <-done:
+			default:
+			}
+		},
+	)
+	if err != nil {
+		sendResponse(responses, done, errorResponse(req.RequestId, err.Error()))
+		return
+	}
+
+	sendResponse(responses, done, &pb.AggregateResponse{
+		RequestId: req.RequestId,
+		Event: &pb.AggregateResponse_Completion{
+			Completion: &pb.Completion{
+				SignedMessage: signedMessage.Bytes(),
+			},
+		},
+	})
+}
+
+// sendResponse delivers resp to responses, but gives up once done is closed
+// (the stream's AggregateSignatures call has returned and nothing is
+// draining responses anymore) so a handleRequest goroutine whose client has
+// gone away can't block forever holding its maxInFlightPerStream slot.
+func sendResponse(responses chan<- *pb.AggregateResponse, done <-chan struct{}, resp *pb.AggregateResponse) {
+	select {
+	case responses <- resp:
+	case <-done:
+	}
+}
+
+func errorResponse(requestID, message string) *pb.AggregateResponse {
+	return &pb.AggregateResponse{
+		RequestId: requestID,
+		Event: &pb.AggregateResponse_Error{
+			Error: &pb.Error{Message: message},
+		},
+	}
+}
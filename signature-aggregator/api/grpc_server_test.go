@@ -0,0 +1,43 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ava-labs/icm-services/signature-aggregator/api/proto/pb"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendResponseDeliversWhenChannelHasRoom(t *testing.T) {
+	responses := make(chan *pb.AggregateResponse, 1)
+	done := make(chan struct{})
+	resp := errorResponse("req-1", "boom")
+
+	sendResponse(responses, done, resp)
+
+	require.Same(t, resp, <-responses)
+}
+
+// TestSendResponseUnblocksOnDone guards against the deadlock/goroutine leak
+// fixed in handleRequest: a caller's final send must not block forever once
+// the stream has ended and nothing is draining responses anymore.
+func TestSendResponseUnblocksOnDone(t *testing.T) {
+	responses := make(chan *pb.AggregateResponse) // unbuffered: a bare send would block forever
+	done := make(chan struct{})
+	close(done)
+
+	finished := make(chan struct{})
+	go func() {
+		sendResponse(responses, done, errorResponse("req-1", "boom"))
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("sendResponse did not unblock once done was closed")
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Package pb holds the Go types and gRPC service plumbing for
+// signature-aggregator/api/proto/aggregator.proto.
+//
+// These are hand-written rather than protoc-generated: this tree has no
+// protoc/buf toolchain wired up to produce them from the .proto source, so
+// the types below are kept in sync with aggregator.proto by hand. The wire
+// codec (see codec.go) is JSON rather than the protobuf binary format as a
+// result, since these types don't implement proto.Message. If a codegen
+// pipeline is added later, this file should be deleted and regenerated, and
+// the server should switch to the default protobuf codec.
+package pb
+
+import "encoding/json"
+
+// AggregateRequest mirrors the aggregator.AggregateRequest proto message.
+type AggregateRequest struct {
+	RequestId        string `json:"request_id,omitempty"`
+	UnsignedMessage  []byte `json:"unsigned_message,omitempty"`
+	Justification    []byte `json:"justification,omitempty"`
+	SigningSubnetId  string `json:"signing_subnet_id,omitempty"`
+	QuorumPercentage uint64 `json:"quorum_percentage,omitempty"`
+}
+
+// Progress mirrors the nested aggregator.AggregateResponse.Progress message.
+type Progress struct {
+	CollectedWeight uint64 `json:"collected_weight,omitempty"`
+	TotalWeight     uint64 `json:"total_weight,omitempty"`
+}
+
+// Completion mirrors the nested aggregator.AggregateResponse.Completion
+// message.
+type Completion struct {
+	SignedMessage []byte `json:"signed_message,omitempty"`
+}
+
+// Error mirrors the nested aggregator.AggregateResponse.Error message.
+type Error struct {
+	Message string `json:"message,omitempty"`
+}
+
+// AggregateResponse mirrors the aggregator.AggregateResponse proto message.
+// Event holds exactly one of *AggregateResponse_Progress,
+// *AggregateResponse_Completion, or *AggregateResponse_Error, mirroring the
+// proto's "event" oneof.
+type AggregateResponse struct {
+	RequestId string
+	Event     isAggregateResponse_Event
+}
+
+type isAggregateResponse_Event interface {
+	isAggregateResponse_Event()
+}
+
+type AggregateResponse_Progress struct {
+	Progress *Progress
+}
+
+type AggregateResponse_Completion struct {
+	Completion *Completion
+}
+
+type AggregateResponse_Error struct {
+	Error *Error
+}
+
+func (*AggregateResponse_Progress) isAggregateResponse_Event()   {}
+func (*AggregateResponse_Completion) isAggregateResponse_Event() {}
+func (*AggregateResponse_Error) isAggregateResponse_Event()      {}
+
+// aggregateResponseWire is the flattened-oneof JSON shape AggregateResponse
+// marshals to and from, matching how protobuf's own JSON mapping represents
+// a oneof (as sibling optional fields, at most one set).
+type aggregateResponseWire struct {
+	RequestId  string      `json:"request_id,omitempty"`
+	Progress   *Progress   `json:"progress,omitempty"`
+	Completion *Completion `json:"completion,omitempty"`
+	Error      *Error      `json:"error,omitempty"`
+}
+
+func (m *AggregateResponse) MarshalJSON() ([]byte, error) {
+	wire := aggregateResponseWire{RequestId: m.RequestId}
+	switch event := m.Event.(type) {
+	case *AggregateResponse_Progress:
+		wire.Progress = event.Progress
+	case *AggregateResponse_Completion:
+		wire.Completion = event.Completion
+	case *AggregateResponse_Error:
+		wire.Error = event.Error
+	}
+	return json.Marshal(wire)
+}
+
+func (m *AggregateResponse) UnmarshalJSON(data []byte) error {
+	var wire aggregateResponseWire
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	m.RequestId = wire.RequestId
+	switch {
+	case wire.Progress != nil:
+		m.Event = &AggregateResponse_Progress{Progress: wire.Progress}
+	case wire.Completion != nil:
+		m.Event = &AggregateResponse_Completion{Completion: wire.Completion}
+	case wire.Error != nil:
+		m.Event = &AggregateResponse_Error{Error: wire.Error}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pb
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AggregatorServer is the server API for the Aggregator service, mirroring
+// what protoc-gen-go-grpc would generate from aggregator.proto.
+type AggregatorServer interface {
+	AggregateSignatures(Aggregator_AggregateSignaturesServer) error
+}
+
+// UnimplementedAggregatorServer can be embedded in an AggregatorServer
+// implementation to satisfy the interface before all methods are written.
+type UnimplementedAggregatorServer struct{}
+
+func (UnimplementedAggregatorServer) AggregateSignatures(Aggregator_AggregateSignaturesServer) error {
+	return status.Error(codes.Unimplemented, "method AggregateSignatures not implemented")
+}
+
+// Aggregator_AggregateSignaturesServer is the bidirectional stream handle an
+// AggregatorServer implementation sends responses on and receives requests
+// from.
+type Aggregator_AggregateSignaturesServer interface {
+	Send(*AggregateResponse) error
+	Recv() (*AggregateRequest, error)
+	grpc.ServerStream
+}
+
+type aggregatorAggregateSignaturesServer struct {
+	grpc.ServerStream
+}
+
+func (s *aggregatorAggregateSignaturesServer) Send(resp *AggregateResponse) error {
+	return s.ServerStream.SendMsg(resp)
+}
+
+func (s *aggregatorAggregateSignaturesServer) Recv() (*AggregateRequest, error) {
+	req := new(AggregateRequest)
+	if err := s.ServerStream.RecvMsg(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func _Aggregator_AggregateSignatures_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AggregatorServer).AggregateSignatures(&aggregatorAggregateSignaturesServer{stream})
+}
+
+// Aggregator_ServiceDesc is the grpc.ServiceDesc for the Aggregator service.
+var Aggregator_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "aggregator.Aggregator",
+	HandlerType: (*AggregatorServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "AggregateSignatures",
+			Handler:       _Aggregator_AggregateSignatures_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "signature-aggregator/api/proto/aggregator.proto",
+}
+
+// RegisterAggregatorServer registers srv against s, matching the signature
+// protoc-gen-go-grpc would generate.
+func RegisterAggregatorServer(s grpc.ServiceRegistrar, srv AggregatorServer) {
+	s.RegisterService(&Aggregator_ServiceDesc, srv)
+}
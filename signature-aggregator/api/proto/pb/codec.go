@@ -0,0 +1,36 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// jsonCodec implements encoding.Codec over plain Go structs, since the types
+// in this package are hand-written rather than protoc-generated and so
+// don't implement proto.Message. ServerCodec returns it so callers can
+// install it on a *grpc.Server via grpc.ForceServerCodec without reaching
+// into the global, process-wide codec registry (which would affect any
+// other gRPC service sharing the process).
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+// ServerCodec returns the encoding.Codec the Aggregator gRPC server must be
+// constructed with, e.g. grpc.NewServer(grpc.ForceServerCodec(pb.ServerCodec())).
+func ServerCodec() encoding.Codec {
+	return jsonCodec{}
+}
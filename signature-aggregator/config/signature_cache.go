@@ -0,0 +1,52 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import "fmt"
+
+const (
+	SignatureCacheBackendMemory = "memory"
+	SignatureCacheBackendBadger = "badger"
+	SignatureCacheBackendRedis  = "redis"
+)
+
+// SignatureCacheConfig configures the SignatureAggregator's persistent
+// signature cache, selectable via the `signature-cache` stanza in the
+// signature-aggregator config file. Backend defaults to in-memory, matching the
+// pre-existing behavior.
+type SignatureCacheConfig struct {
+	// Backend is one of "memory", "badger", or "redis".
+	Backend string `mapstructure:"backend" json:"backend"`
+
+	// MaxSize bounds the number of entries kept by the "memory" backend.
+	MaxSize int `mapstructure:"max-size" json:"max-size"`
+
+	// BadgerDBPath is the on-disk directory used by the "badger" backend.
+	BadgerDBPath string `mapstructure:"badger-db-path" json:"badger-db-path"`
+
+	// RedisAddr, RedisPassword, and RedisDB configure the "redis" backend.
+	RedisAddr     string `mapstructure:"redis-addr" json:"redis-addr"`
+	RedisPassword string `mapstructure:"redis-password" json:"redis-password"`
+	RedisDB       int    `mapstructure:"redis-db" json:"redis-db"`
+}
+
+// Validate checks that the configured backend is recognized and that its
+// required fields are set.
+func (c *SignatureCacheConfig) Validate() error {
+	switch c.Backend {
+	case "", SignatureCacheBackendMemory:
+		c.Backend = SignatureCacheBackendMemory
+	case SignatureCacheBackendBadger:
+		if c.BadgerDBPath == "" {
+			return fmt.Errorf("signature-cache.badger-db-path is required when backend is %q", SignatureCacheBackendBadger)
+		}
+	case SignatureCacheBackendRedis:
+		if c.RedisAddr == "" {
+			return fmt.Errorf("signature-cache.redis-addr is required when backend is %q", SignatureCacheBackendRedis)
+		}
+	default:
+		return fmt.Errorf("unrecognized signature-cache.backend %q", c.Backend)
+	}
+	return nil
+}
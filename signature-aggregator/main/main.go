@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 
@@ -16,15 +17,21 @@ import (
 	"github.com/ava-labs/icm-services/peers"
 	"github.com/ava-labs/icm-services/signature-aggregator/aggregator"
 	"github.com/ava-labs/icm-services/signature-aggregator/api"
+	"github.com/ava-labs/icm-services/signature-aggregator/api/proto/pb"
 	"github.com/ava-labs/icm-services/signature-aggregator/config"
 	"github.com/ava-labs/icm-services/signature-aggregator/healthcheck"
 	"github.com/ava-labs/icm-services/signature-aggregator/metrics"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 var version = "v0.0.0-dev"
 
+// messageQueueWorkers bounds how many CreateSignedMessage jobs the
+// per-subnet fairness scheduler runs concurrently across all subnets.
+const messageQueueWorkers = 8
+
 func main() {
 	fs := config.BuildFlagSet()
 	if err := fs.Parse(os.Args[1:]); err != nil {
@@ -133,6 +140,69 @@ func main() {
 		logger.Fatal("Failed to create signature aggregator", zap.Error(err))
 		panic(err)
 	}
+
+	signatureCacheMetrics, err := metrics.NewSignatureCacheMetrics(registry)
+	if err != nil {
+		logger.Fatal("Failed to create signature cache metrics", zap.Error(err))
+		panic(err)
+	}
+	if cfg.SignatureCache.MaxSize == 0 {
+		// The signature-cache stanza is a separate config field from the
+		// pre-existing signature-cache-size; default its MaxSize from that
+		// field so a deployment that upgrades without adding the new stanza
+		// keeps its prior in-memory cache size instead of silently dropping
+		// to an uncached (size-0) memory backend.
+		cfg.SignatureCache.MaxSize = int(cfg.SignatureCacheSize)
+	}
+	if err := cfg.SignatureCache.Validate(); err != nil {
+		logger.Fatal("Invalid signature-cache config", zap.Error(err))
+		panic(err)
+	}
+	signatureCache, err := aggregator.NewSignatureCache(cfg.SignatureCache, signatureCacheMetrics, logger)
+	if err != nil {
+		logger.Fatal("Failed to create signature cache", zap.Error(err))
+		panic(err)
+	}
+	signatureAggregator.SetSignatureCache(signatureCache)
+	if warmer, ok := signatureCache.(interface{ WarmUp() (int, error) }); ok {
+		if count, err := warmer.WarmUp(); err != nil {
+			logger.Warn("Failed to warm signature cache", zap.Error(err))
+		} else {
+			logger.Info("Warmed signature cache", zap.Int("entries", count))
+		}
+	}
+
+	queueMetrics, err := metrics.NewMessageQueueMetrics(registry)
+	if err != nil {
+		logger.Fatal("Failed to create message queue metrics", zap.Error(err))
+		panic(err)
+	}
+	messageQueue := aggregator.NewMessageQueue(messageQueueWorkers, queueMetrics)
+	defer messageQueue.Close()
+	for _, subnetID := range cfg.GetTrackedSubnets().List() {
+		connectedValidators, err := network.GetConnectedCanonicalValidators(subnetID)
+		if err != nil {
+			logger.Warn(
+				"Failed to get connected validators for initial message queue stake weight",
+				zap.Stringer("subnetID", subnetID),
+				zap.Error(err),
+			)
+			continue
+		}
+		messageQueue.SetSubnetStakeWeight(subnetID, connectedValidators.TotalValidatorWeight)
+	}
+	signatureAggregator.SetMessageQueue(messageQueue)
+
+	// SetNetworkMetrics is intentionally not called here: peers.NewNetwork
+	// already owns the one AppRequestNetworkMetrics instance registered
+	// against this registerer (for info_api_call_latency_ms and
+	// p_chain_api_call_latency_ms), and building a second one would attempt
+	// to re-register the same metric names and panic. Wiring this requires
+	// peers.NewNetwork to expose that existing instance; until it does,
+	// validator outcomes still reach ValidatorScoreTracker (and therefore
+	// adaptive selection) via RecordResult, just without the Prometheus
+	// histograms/counters.
+
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		host := r.Host
 		exampleRequest := `curl --location 'https://` + host + `/aggregate-signatures' \
@@ -151,12 +221,32 @@ func main() {
 		metricsInstance,
 		signatureAggregator,
 	)
+	api.HandleBatchAggregateSignaturesRequest(
+		logger,
+		metricsInstance,
+		signatureAggregator,
+	)
+	api.HandleDebugValidatorsRequest(logger, signatureAggregator.ValidatorScores())
 
 	healthCheckSubnets := cfg.GetTrackedSubnets().List()
 	healthCheckSubnets = append(healthCheckSubnets, constants.PrimaryNetworkID)
 	networkHealthcheckFunc := peers.GetNetworkHealthFunc(network, healthCheckSubnets)
 	healthcheck.HandleHealthCheckRequest(networkHealthcheckFunc)
 
+	grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+	if err != nil {
+		logger.Fatal("Failed to open gRPC listener", zap.Error(err))
+		panic(err)
+	}
+	grpcServer := grpc.NewServer(grpc.ForceServerCodec(pb.ServerCodec()))
+	api.RegisterGRPCServer(grpcServer, logger, signatureAggregator)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Error("gRPC server error", zap.Error(err))
+		}
+	}()
+	defer grpcServer.GracefulStop()
+
 	logger.Info("Initialization complete")
 	err = http.ListenAndServe(fmt.Sprintf(":%d", cfg.APIPort), nil)
 	if errors.Is(err, http.ErrServerClosed) {
@@ -0,0 +1,81 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrFailedToCreateMessageQueueMetrics = errors.New("failed to create message queue metrics")
+
+// MessageQueueMetrics tracks the depth, wait time, and per-subnet
+// concurrency of the aggregator's fairness-scheduled MessageQueue.
+type MessageQueueMetrics struct {
+	QueueDepth  *prometheus.GaugeVec
+	WaitTimeMS  *prometheus.HistogramVec
+	InFlight    *prometheus.GaugeVec
+	DroppedJobs *prometheus.CounterVec
+}
+
+// NewMessageQueueMetrics registers and returns the message queue metrics
+// against registerer.
+func NewMessageQueueMetrics(registerer prometheus.Registerer) (*MessageQueueMetrics, error) {
+	queueDepth := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "message_queue_depth",
+			Help: "Number of pending CreateSignedMessage jobs queued for a subnet",
+		},
+		[]string{"subnet_id"},
+	)
+	if queueDepth == nil {
+		return nil, ErrFailedToCreateMessageQueueMetrics
+	}
+	registerer.MustRegister(queueDepth)
+
+	waitTimeMS := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "message_queue_wait_time_ms",
+			Help:    "Time a job spent queued before being dequeued, in milliseconds",
+			Buckets: prometheus.ExponentialBucketsRange(1, 60000, 10),
+		},
+		[]string{"subnet_id"},
+	)
+	if waitTimeMS == nil {
+		return nil, ErrFailedToCreateMessageQueueMetrics
+	}
+	registerer.MustRegister(waitTimeMS)
+
+	inFlight := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "message_queue_in_flight",
+			Help: "Number of CreateSignedMessage jobs currently executing for a subnet",
+		},
+		[]string{"subnet_id"},
+	)
+	if inFlight == nil {
+		return nil, ErrFailedToCreateMessageQueueMetrics
+	}
+	registerer.MustRegister(inFlight)
+
+	droppedJobs := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "message_queue_dropped_jobs",
+			Help: "Number of jobs dropped for exceeding their deadline while queued",
+		},
+		[]string{"subnet_id"},
+	)
+	if droppedJobs == nil {
+		return nil, ErrFailedToCreateMessageQueueMetrics
+	}
+	registerer.MustRegister(droppedJobs)
+
+	return &MessageQueueMetrics{
+		QueueDepth:  queueDepth,
+		WaitTimeMS:  waitTimeMS,
+		InFlight:    inFlight,
+		DroppedJobs: droppedJobs,
+	}, nil
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2024, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package metrics
+
+import (
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var ErrFailedToCreateSignatureCacheMetrics = errors.New("failed to create signature cache metrics")
+
+// SignatureCacheMetrics tracks hit/miss counts for the SignatureAggregator's
+// persistent signature cache, independent of which backend (in-memory,
+// BadgerDB, Redis) is selected.
+type SignatureCacheMetrics struct {
+	Hits   prometheus.Counter
+	Misses prometheus.Counter
+}
+
+// NewSignatureCacheMetrics registers and returns the signature cache metrics
+// against registerer.
+func NewSignatureCacheMetrics(registerer prometheus.Registerer) (*SignatureCacheMetrics, error) {
+	hits := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "signature_cache_hits",
+		Help: "Number of signature cache lookups (BLS shares and persisted validator scores) that found an entry",
+	})
+	if hits == nil {
+		return nil, ErrFailedToCreateSignatureCacheMetrics
+	}
+	registerer.MustRegister(hits)
+
+	misses := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "signature_cache_misses",
+		Help: "Number of signature cache lookups (BLS shares and persisted validator scores) that did not find an entry",
+	})
+	if misses == nil {
+		return nil, ErrFailedToCreateSignatureCacheMetrics
+	}
+	registerer.MustRegister(misses)
+
+	return &SignatureCacheMetrics{
+		Hits:   hits,
+		Misses: misses,
+	}, nil
+}